@@ -0,0 +1,94 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "unicode/utf8"
+
+// CJKSegmenter splits one breaker-delimited run of CJK-script bytes (as
+// passed to outputCJK) into the byte spans that become individual tokens,
+// each given as a [start,end) pair into the run, in emission order.
+type CJKSegmenter interface {
+	Segment(buf []byte) [][2]int
+}
+
+// NGramSegmenter segments a CJK run into overlapping N-rune windows,
+// advancing one rune at a time and shrinking the window over the run's
+// final N-1 runes so they still produce (shorter) tail tokens instead of
+// being dropped. N=3 reproduces the tokenizer's original trigram behavior.
+type NGramSegmenter struct {
+	N int
+}
+
+// Segment implements CJKSegmenter.
+func (s NGramSegmenter) Segment(buf []byte) [][2]int {
+	n := s.N
+	if n < 1 {
+		n = 1
+	}
+
+	offsets := make([]int, 0, len(buf)+1)
+	offsets = append(offsets, 0)
+	for pos := 0; pos < len(buf); {
+		_, sz := utf8.DecodeRune(buf[pos:])
+		pos += sz
+		offsets = append(offsets, pos)
+	}
+	runeCount := len(offsets) - 1
+
+	spans := make([][2]int, 0, runeCount)
+	for ia := 0; ia < runeCount; ia++ {
+		id := ia + n
+		if id > runeCount {
+			id = runeCount
+		}
+		spans = append(spans, [2]int{offsets[ia], offsets[id]})
+	}
+	return spans
+}
+
+// MaxMatchSegmenter segments a CJK run by forward maximum matching against
+// Dict: scanning left to right, it emits the longest dictionary term
+// starting at the current position, or a single rune if none matches, and
+// resumes just past whatever it emitted.
+type MaxMatchSegmenter struct {
+	Dict *Dictionary
+}
+
+// Segment implements CJKSegmenter.
+func (s MaxMatchSegmenter) Segment(buf []byte) [][2]int {
+	var spans [][2]int
+	pos := 0
+	for _, m := range s.Dict.FindMatches(buf) {
+		for pos < m.Start {
+			_, sz := utf8.DecodeRune(buf[pos:])
+			spans = append(spans, [2]int{pos, pos + sz})
+			pos += sz
+		}
+		spans = append(spans, [2]int{m.Start, m.Start + m.Len})
+		pos = m.Start + m.Len
+	}
+	for pos < len(buf) {
+		_, sz := utf8.DecodeRune(buf[pos:])
+		spans = append(spans, [2]int{pos, pos + sz})
+		pos += sz
+	}
+	return spans
+}
+
+// WithCJKSegmenter selects how CJK runs are split into tokens. The default,
+// used when this option is not given, is NGramSegmenter{N: 3}.
+func WithCJKSegmenter(s CJKSegmenter) Option {
+	return func(c *tokenizerConfig) { c.cjkSegmenter = s }
+}
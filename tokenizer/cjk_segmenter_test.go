@@ -0,0 +1,75 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "testing"
+
+func TestWithCJKSegmenterUnigram(t *testing.T) {
+	checkTokenize(t, "我的书很好看了", []Token{
+		makeToken("我", 0),
+		makeToken("的", 1),
+		makeToken("书", 2),
+		makeToken("很", 3),
+		makeToken("好", 4),
+		makeToken("看", 5),
+		makeToken("了", 6),
+	}, WithCJKSegmenter(NGramSegmenter{N: 1}))
+}
+
+func TestWithCJKSegmenterBigram(t *testing.T) {
+	checkTokenize(t, "我的书很好看了", []Token{
+		makeToken("我的", 0),
+		makeToken("的书", 1),
+		makeToken("书很", 2),
+		makeToken("很好", 3),
+		makeToken("好看", 4),
+		makeToken("看了", 5),
+		makeToken("了", 6),
+	}, WithCJKSegmenter(NGramSegmenter{N: 2}))
+}
+
+func TestWithCJKSegmenterDefaultMatchesTrigram(t *testing.T) {
+	// the default (no WithCJKSegmenter) must keep behaving exactly like
+	// NGramSegmenter{N: 3}, the tokenizer's original hardcoded behavior.
+	input := "我的书很好看了"
+	withDefault := tokenize([]byte(input))
+	withExplicitN3 := tokenize([]byte(input), WithCJKSegmenter(NGramSegmenter{N: 3}))
+	if len(withDefault) != len(withExplicitN3) {
+		t.Fatalf("default segmenter = %v, want %v", withDefault, withExplicitN3)
+	}
+	for i, tk := range withDefault {
+		if tk != withExplicitN3[i] {
+			t.Errorf("default segmenter = %v, want %v", withDefault, withExplicitN3)
+			return
+		}
+	}
+}
+
+func TestWithCJKSegmenterMaxMatch(t *testing.T) {
+	d := NewDictionary([]string{"紐約", "中华人民共和国", "很好"})
+	checkTokenize(t, "我住在紐約很好看了去中华人民共和国旅游", []Token{
+		makeToken("我", 0),
+		makeToken("住", 1),
+		makeToken("在", 2),
+		makeToken("紐約", 3),
+		makeToken("很好", 4),
+		makeToken("看", 5),
+		makeToken("了", 6),
+		makeToken("去", 7),
+		makeToken("中华人民共和国", 8),
+		makeToken("旅", 9),
+		makeToken("游", 10),
+	}, WithCJKSegmenter(MaxMatchSegmenter{Dict: d}))
+}
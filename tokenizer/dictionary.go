@@ -0,0 +1,203 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// dictNode is one state of a Dictionary's Aho-Corasick automaton.
+type dictNode struct {
+	children map[rune]*dictNode
+	fail     *dictNode
+
+	// output holds the rune-length of every dictionary term that ends at
+	// this node, including terms reached only through fail links (i.e.
+	// terms that are a suffix of the path to this node).
+	output []int
+}
+
+// Dictionary is a set of terms (stopwords, phrases, named entities, ...)
+// compiled into an Aho-Corasick automaton: a goto trie keyed on rune, with
+// failure links computed by BFS so the whole input can be scanned in a
+// single pass regardless of how many terms it contains.
+type Dictionary struct {
+	root *dictNode
+}
+
+// NewDictionary compiles terms into a Dictionary. Empty terms are ignored.
+func NewDictionary(terms []string) *Dictionary {
+	d := &Dictionary{root: newDictNode()}
+	for _, term := range terms {
+		runes := []rune(term)
+		if len(runes) == 0 {
+			continue
+		}
+		n := d.root
+		for _, r := range runes {
+			c, ok := n.children[r]
+			if !ok {
+				c = newDictNode()
+				n.children[r] = c
+			}
+			n = c
+		}
+		n.output = append(n.output, len(runes))
+	}
+	d.buildFailLinks()
+	return d
+}
+
+func newDictNode() *dictNode {
+	return &dictNode{children: make(map[rune]*dictNode)}
+}
+
+// buildFailLinks computes each node's failure link by BFS over the goto
+// trie, and merges in the output of the node a failure link points to so
+// that output lookups don't need to walk the fail chain themselves.
+func (d *Dictionary) buildFailLinks() {
+	queue := make([]*dictNode, 0, len(d.root.children))
+	for _, c := range d.root.children {
+		c.fail = d.root
+		queue = append(queue, c)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for r, c := range n.children {
+			queue = append(queue, c)
+
+			f := n.fail
+			for f != nil {
+				if next, ok := f.children[r]; ok {
+					c.fail = next
+					break
+				}
+				f = f.fail
+			}
+			if f == nil {
+				c.fail = d.root
+			}
+			c.output = append(c.output, c.fail.output...)
+		}
+	}
+}
+
+// step advances node by one rune using the goto function, falling back
+// through failure links when there is no direct child for r.
+func (d *Dictionary) step(node *dictNode, r rune) *dictNode {
+	for {
+		if c, ok := node.children[r]; ok {
+			return c
+		}
+		if node == d.root {
+			return d.root
+		}
+		node = node.fail
+	}
+}
+
+// Contains reports whether s, taken as a whole, is exactly one of the
+// dictionary's terms.
+func (d *Dictionary) Contains(s []byte) bool {
+	node := d.root
+	runeCount := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		c, ok := node.children[r]
+		if !ok {
+			return false
+		}
+		node = c
+		runeCount++
+		i += size
+	}
+	for _, l := range node.output {
+		if l == runeCount {
+			return true
+		}
+	}
+	return false
+}
+
+// WithStopwords drops tokens that exactly match a term in d: they still
+// advance the tokenizer's currTokenPos, but are not yielded.
+func WithStopwords(d *Dictionary) Option {
+	return func(c *tokenizerConfig) { c.stopwords = d }
+}
+
+// WithPhraseDict recognizes the longest term in d anchored at the earliest
+// available position as a single token carrying its raw bytes, in place of
+// the default Latin/CJK handling for the span it covers. Only available on
+// constructors that take the whole input as a []byte (NewSimpleTokenizer,
+// NewSimpleTokenizerIn), since matches are found by scanning the full input
+// once up front.
+func WithPhraseDict(d *Dictionary) Option {
+	return func(c *tokenizerConfig) { c.phraseDict = d }
+}
+
+// Match is a span of input, in bytes, that matched one of a Dictionary's
+// terms.
+type Match struct {
+	Start int
+	Len   int
+}
+
+// FindMatches scans input once with the automaton and returns the set of
+// non-overlapping matches obtained by, left to right, always preferring
+// the longest match anchored at the earliest available start.
+func (d *Dictionary) FindMatches(input []byte) []Match {
+	type runeMatch struct{ startRune, endRune int }
+
+	offsets := make([]int, 0, len(input)+1)
+	var raw []runeMatch
+
+	node := d.root
+	runeIdx := 0
+	for pos := 0; pos < len(input); {
+		offsets = append(offsets, pos)
+		r, size := utf8.DecodeRune(input[pos:])
+		node = d.step(node, r)
+		runeIdx++
+
+		for _, l := range node.output {
+			raw = append(raw, runeMatch{startRune: runeIdx - l, endRune: runeIdx})
+		}
+		pos += size
+	}
+	offsets = append(offsets, len(input))
+
+	sort.Slice(raw, func(i, j int) bool {
+		if raw[i].startRune != raw[j].startRune {
+			return raw[i].startRune < raw[j].startRune
+		}
+		return raw[i].endRune > raw[j].endRune
+	})
+
+	var matches []Match
+	cursor := 0
+	for _, m := range raw {
+		if m.startRune < cursor {
+			continue
+		}
+		matches = append(matches, Match{
+			Start: offsets[m.startRune],
+			Len:   offsets[m.endRune] - offsets[m.startRune],
+		})
+		cursor = m.endRune
+	}
+	return matches
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "testing"
+
+func TestDictionaryContains(t *testing.T) {
+	d := NewDictionary([]string{"she", "he", "hers", "his"})
+	for _, term := range []string{"she", "he", "hers", "his"} {
+		if !d.Contains([]byte(term)) {
+			t.Errorf("Contains(%q) = false, want true", term)
+		}
+	}
+	for _, term := range []string{"her", "shis", ""} {
+		if d.Contains([]byte(term)) {
+			t.Errorf("Contains(%q) = true, want false", term)
+		}
+	}
+}
+
+func TestDictionaryFindMatchesOverlap(t *testing.T) {
+	// classic Aho-Corasick example: "she", "he", "hers" overlap in "ushers".
+	// FindMatches prefers the earliest start over a longer match that starts
+	// later, so "she" (Start:1, Len:3) wins even though "hers" (Start:2,
+	// Len:4) is longer.
+	d := NewDictionary([]string{"he", "she", "his", "hers"})
+	matches := d.FindMatches([]byte("ushers"))
+	if len(matches) != 1 || matches[0].Start != 1 || matches[0].Len != 3 {
+		t.Errorf("FindMatches(ushers) = %v, want one match {Start:1 Len:3} (\"she\")", matches)
+	}
+}
+
+func TestWithStopwordsLatin(t *testing.T) {
+	d := NewDictionary([]string{"the", "of"})
+	checkTokenize(t, "the quick fox of the hill", []Token{
+		makeToken("quick", 1),
+		makeToken("fox", 2),
+		makeToken("hill", 5),
+	}, WithStopwords(d))
+}
+
+func TestWithStopwordsCJK(t *testing.T) {
+	// single-character stopwords only drop the tail n-gram that matches them
+	// exactly; a larger n-gram that merely contains the stopword is kept.
+	d := NewDictionary([]string{"的", "了"})
+	checkTokenize(t, "我的书很好看了", []Token{
+		makeToken("我的书", 0),
+		makeToken("的书很", 1),
+		makeToken("书很好", 2),
+		makeToken("很好看", 3),
+		makeToken("好看了", 4),
+		makeToken("看了", 5),
+	}, WithStopwords(d))
+}
+
+func TestWithPhraseDictLatin(t *testing.T) {
+	d := NewDictionary([]string{"new york", "hill"})
+	checkTokenize(t, "i live in new york city on the hill", []Token{
+		makeToken("live", 1),
+		makeToken("in", 2),
+		makeToken("new york", 3),
+		makeToken("city", 4),
+		makeToken("on", 5),
+		makeToken("the", 6),
+		makeToken("hill", 7),
+	}, WithPhraseDict(d))
+}
+
+func TestWithPhraseDictCJK(t *testing.T) {
+	// a phrase starting partway through a breaker-free CJK run must still be
+	// recognized, interrupting the surrounding n-grams rather than only
+	// matching at the start of the run.
+	d := NewDictionary([]string{"紐約", "中华人民共和国"})
+	checkTokenize(t, "我住在紐約很久了去中华人民共和国旅游", []Token{
+		makeToken("我住在", 0),
+		makeToken("住在", 1),
+		makeToken("在", 2),
+		makeToken("紐約", 3),
+		makeToken("很久了", 4),
+		makeToken("久了去", 5),
+		makeToken("了去", 6),
+		makeToken("去", 7),
+		makeToken("中华人民共和国", 8),
+		makeToken("旅游", 9),
+		makeToken("游", 10),
+	}, WithPhraseDict(d))
+}
+
+func TestWithStopwordsAndPhraseDictTogether(t *testing.T) {
+	stopwords := NewDictionary([]string{"the"})
+	phrases := NewDictionary([]string{"new york"})
+	checkTokenize(t, "the new york times", []Token{
+		makeToken("new york", 1),
+		makeToken("times", 2),
+	}, WithStopwords(stopwords), WithPhraseDict(phrases))
+}
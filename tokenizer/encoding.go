@@ -0,0 +1,171 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// detectSampleSize is how much of the input WithAutoDetectEncoding looks at
+// before committing to an encoding.
+const detectSampleSize = 4 * 1024
+
+// Option configures a tokenizer constructor: input encoding, stopwords,
+// phrase recognition, etc.
+type Option func(*tokenizerConfig)
+
+type tokenizerConfig struct {
+	enc        encoding.Encoding
+	autoDetect bool
+
+	stopwords    *Dictionary
+	phraseDict   *Dictionary
+	cjkSegmenter CJKSegmenter
+}
+
+// WithEncoding decodes the tokenizer's input from enc to UTF-8 before the
+// state machine runs, so callers no longer have to wrap their own
+// transform.Reader around GBK, Big5, Shift-JIS, EUC-KR, Windows-1252, etc.
+func WithEncoding(enc encoding.Encoding) Option {
+	return func(c *tokenizerConfig) { c.enc = enc }
+}
+
+// WithAutoDetectEncoding sniffs the input's encoding from a BOM, or failing
+// that a byte-frequency heuristic over the first few KB, and decodes
+// accordingly. The chosen encoding.Encoding is available afterwards via
+// SimpleTokenizer.Encoding.
+func WithAutoDetectEncoding() Option {
+	return func(c *tokenizerConfig) { c.autoDetect = true }
+}
+
+func newTokenizerConfig(opts []Option) *tokenizerConfig {
+	c := &tokenizerConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Encoding returns the encoding that was used to decode this tokenizer's
+// input, or nil if no encoding was configured via WithEncoding or
+// WithAutoDetectEncoding.
+func (t *SimpleTokenizer) Encoding() encoding.Encoding {
+	return t.encoding
+}
+
+// detectEncoding sniffs the encoding of sample: a UTF-8/UTF-16 BOM if
+// present, otherwise plain UTF-8 if sample already validates as such,
+// otherwise the better scoring of GBK and Big5 by lead/trail byte
+// frequency.
+func detectEncoding(sample []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	}
+
+	if utf8.Valid(sample) {
+		return encoding.Nop
+	}
+
+	return scoreDoubleByteEncoding(sample)
+}
+
+// scoreDoubleByteEncoding scores sample's bytes against the lead/trail byte
+// ranges of GBK and Big5 and returns whichever scores higher, defaulting to
+// GBK on a tie since it is the more common of the two.
+func scoreDoubleByteEncoding(sample []byte) encoding.Encoding {
+	var gbkScore, big5Score int
+	for i := 0; i < len(sample)-1; i++ {
+		lead, trail := sample[i], sample[i+1]
+		if lead < 0x81 || lead == 0xFF {
+			continue
+		}
+		if isGBKTrailByte(trail) {
+			gbkScore++
+		}
+		if isBig5TrailByte(trail) {
+			big5Score++
+		}
+		i++ // the pair is consumed together
+	}
+
+	if big5Score > gbkScore {
+		return traditionalchinese.Big5
+	}
+	return simplifiedchinese.GBK
+}
+
+func isGBKTrailByte(b byte) bool {
+	return b >= 0x40 && b != 0x7F && b <= 0xFE
+}
+
+func isBig5TrailByte(b byte) bool {
+	return (b >= 0x40 && b <= 0x7E) || (b >= 0xA1 && b <= 0xFE)
+}
+
+// decodeBytes resolves cfg to a concrete encoding (sniffing from sample
+// when cfg.autoDetect is set) and, if one was found, decodes input to
+// UTF-8. It returns the possibly-decoded input and the encoding used, which
+// is nil if neither WithEncoding nor WithAutoDetectEncoding was given.
+func decodeBytes(cfg *tokenizerConfig, input []byte) ([]byte, encoding.Encoding, error) {
+	enc := cfg.enc
+	if enc == nil && cfg.autoDetect {
+		sample := input
+		if len(sample) > detectSampleSize {
+			sample = sample[:detectSampleSize]
+		}
+		enc = detectEncoding(sample)
+	}
+	if enc == nil || enc == encoding.Nop {
+		return input, enc, nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(input)
+	if err != nil {
+		return input, enc, err
+	}
+	return decoded, enc, nil
+}
+
+// decodeReader is the io.Reader counterpart of decodeBytes: it peeks up to
+// detectSampleSize bytes from r to auto-detect if requested, then wraps r
+// (if needed) with a decoding reader for the resolved encoding.
+func decodeReader(cfg *tokenizerConfig, r io.Reader) (io.Reader, encoding.Encoding, error) {
+	enc := cfg.enc
+	if enc == nil && cfg.autoDetect {
+		sample := make([]byte, detectSampleSize)
+		n, err := io.ReadFull(r, sample)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return r, nil, err
+		}
+		sample = sample[:n]
+		enc = detectEncoding(sample)
+		r = io.MultiReader(bytes.NewReader(sample), r)
+	}
+	if enc == nil || enc == encoding.Nop {
+		return r, enc, nil
+	}
+	return enc.NewDecoder().Reader(r), enc, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestWithEncodingGBK(t *testing.T) {
+	const text = "中华铅笔2B的好用"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode GBK fixture: %v", err)
+	}
+
+	want := tokenize([]byte(text))
+	tknz := NewSimpleTokenizer(gbk, WithEncoding(simplifiedchinese.GBK))
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("WithEncoding(GBK) tokens = %v, want %v", got, want)
+	}
+	for i, tk := range got {
+		if tk != want[i] {
+			t.Errorf("WithEncoding(GBK) tokens = %v, want %v", got, want)
+			return
+		}
+	}
+	if tknz.Encoding() != simplifiedchinese.GBK {
+		t.Errorf("Encoding() = %v, want GBK", tknz.Encoding())
+	}
+}
+
+func TestWithAutoDetectEncodingGBK(t *testing.T) {
+	const text = "中华铅笔2B的好用"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode GBK fixture: %v", err)
+	}
+
+	want := tokenize([]byte(text))
+	tknz := NewSimpleTokenizer(gbk, WithAutoDetectEncoding())
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("WithAutoDetectEncoding() over GBK tokens = %v, want %v", got, want)
+	}
+	for i, tk := range got {
+		if tk != want[i] {
+			t.Errorf("WithAutoDetectEncoding() over GBK tokens = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+func TestWithAutoDetectEncodingPlainUTF8(t *testing.T) {
+	const text = "hello, world"
+	tknz := NewSimpleTokenizer([]byte(text), WithAutoDetectEncoding())
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+	want := tokenize([]byte(text))
+	if len(got) != len(want) {
+		t.Fatalf("WithAutoDetectEncoding() over UTF-8 tokens = %v, want %v", got, want)
+	}
+	if tknz.Encoding() != encoding.Nop {
+		t.Errorf("Encoding() = %v, want encoding.Nop for already-UTF-8 input", tknz.Encoding())
+	}
+}
+
+func TestDetectEncodingUTF8BOM(t *testing.T) {
+	bom := []byte{0xEF, 0xBB, 0xBF}
+	input := append(bom, []byte("hello")...)
+	if enc := detectEncoding(input); enc != unicode.UTF8BOM {
+		t.Errorf("detectEncoding(UTF-8 BOM) = %v, want unicode.UTF8BOM", enc)
+	}
+}
+
+func TestStreamWithEncodingGBK(t *testing.T) {
+	const text = "中华铅笔2B的好用"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode GBK fixture: %v", err)
+	}
+
+	want := tokenize([]byte(text))
+	tknz := NewStreamTokenizer(bytes.NewReader(gbk), WithEncoding(simplifiedchinese.GBK))
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("stream WithEncoding(GBK) tokens = %v, want %v", got, want)
+	}
+	for i, tk := range got {
+		if tk != want[i] {
+			t.Errorf("stream WithEncoding(GBK) tokens = %v, want %v", got, want)
+			return
+		}
+	}
+}
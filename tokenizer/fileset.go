@@ -0,0 +1,118 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "sort"
+
+// File tracks the name, size and line-start offsets of one file registered
+// with a FileSet, modeled on go/token.File. Tokenizers bound to a File via
+// NewSimpleTokenizerIn record line breaks here as they scan, so that
+// FileSet.Position can later resolve any Token's FilePos to a line/column.
+type File struct {
+	set  *FileSet
+	name string
+	base int
+	size int
+
+	// lines holds the byte offset (relative to the start of this file) of
+	// the first byte of each line; lines[0] is always 0.
+	lines []int
+}
+
+// Name returns the file name as registered with AddFile.
+func (f *File) Name() string { return f.name }
+
+// Base returns the offset at which this file's positions start in its
+// FileSet.
+func (f *File) Base() int { return f.base }
+
+// Size returns the file size as registered with AddFile.
+func (f *File) Size() int { return f.size }
+
+// AddLine records the offset of the start of a new line. Offsets must be
+// added in increasing order; out-of-range or non-increasing offsets are
+// ignored.
+func (f *File) AddLine(offset int) {
+	if offset < 0 || offset >= f.size {
+		return
+	}
+	if n := len(f.lines); n == 0 || f.lines[n-1] < offset {
+		f.lines = append(f.lines, offset)
+	}
+}
+
+// lineColumn resolves a byte offset within this file to a 1-based line and
+// column.
+func (f *File) lineColumn(offset int) (line, column int) {
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset })
+	line = i // lines is 0-indexed, line numbers are 1-based: lines[i-1] starts line i
+	return line, offset - f.lines[i-1] + 1
+}
+
+// FileSet tracks the set of files a tokenizer has been run over so that the
+// TokenPos carried by a Token can be resolved back to a filename, byte
+// offset, line and column. It is modeled on go/token.FileSet.
+type FileSet struct {
+	files []*File
+	base  int
+}
+
+// NewFileSet creates an empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile registers a new file of the given name and size, returning a
+// *File whose positions are reserved in this FileSet's global pos space.
+// Pass the File to NewSimpleTokenizerIn to tokenize its content.
+func (s *FileSet) AddFile(name string, size int) *File {
+	f := &File{set: s, name: name, base: s.base, size: size, lines: []int{0}}
+	s.files = append(s.files, f)
+	s.base += size + 1
+	return f
+}
+
+// file returns the File containing pos, or nil if pos does not belong to
+// any file registered with s.
+func (s *FileSet) file(pos TokenPos) *File {
+	p := int(pos)
+	for _, f := range s.files {
+		if p >= f.base && p <= f.base+f.size {
+			return f
+		}
+	}
+	return nil
+}
+
+// Position describes a resolved source location, modeled on
+// go/token.Position.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// Position resolves pos (as found in Token.FilePos) to a Position. It
+// returns the zero Position if pos does not belong to any file in s.
+func (s *FileSet) Position(pos TokenPos) Position {
+	f := s.file(pos)
+	if f == nil {
+		return Position{}
+	}
+	offset := int(pos) - f.base
+	line, column := f.lineColumn(offset)
+	return Position{Filename: f.name, Offset: offset, Line: line, Column: column}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "testing"
+
+func TestFileSetPosition(t *testing.T) {
+	text := "hello world\nfoo bar\n相见时难"
+	fset := NewFileSet()
+	f := fset.AddFile("doc.txt", len(text))
+
+	var tokens []Token
+	tknz := NewSimpleTokenizerIn(f, []byte(text))
+	for tk := range tknz.Tokenize() {
+		tokens = append(tokens, tk)
+	}
+
+	checkPos := func(i int, wantLine, wantColumn int) {
+		pos := fset.Position(tokens[i].FilePos)
+		if pos.Filename != "doc.txt" || pos.Line != wantLine || pos.Column != wantColumn {
+			t.Errorf("token %d (%q) Position = %+v, want line %d column %d",
+				i, tokens[i].TokenBytes[1:1+tokens[i].TokenBytes[0]], pos, wantLine, wantColumn)
+		}
+	}
+
+	// "hello"
+	checkPos(0, 1, 1)
+	// "world"
+	checkPos(1, 1, 7)
+	// "foo"
+	checkPos(2, 2, 1)
+	// "bar"
+	checkPos(3, 2, 5)
+	// "相见时"
+	checkPos(4, 3, 1)
+}
+
+func TestFileSetMultipleFiles(t *testing.T) {
+	fset := NewFileSet()
+	f1 := fset.AddFile("a.txt", 5)
+	f2 := fset.AddFile("b.txt", 5)
+
+	var got1, got2 []Token
+	for tk := range NewSimpleTokenizerIn(f1, []byte("hello")).Tokenize() {
+		got1 = append(got1, tk)
+	}
+	for tk := range NewSimpleTokenizerIn(f2, []byte("world")).Tokenize() {
+		got2 = append(got2, tk)
+	}
+
+	p1 := fset.Position(got1[0].FilePos)
+	if p1.Filename != "a.txt" || p1.Offset != 0 {
+		t.Errorf("Position(%d) = %+v, want a.txt offset 0", got1[0].FilePos, p1)
+	}
+	p2 := fset.Position(got2[0].FilePos)
+	if p2.Filename != "b.txt" || p2.Offset != 0 {
+		t.Errorf("Position(%d) = %+v, want b.txt offset 0", got2[0].FilePos, p2)
+	}
+}
+
+func TestUnboundTokenizerHasZeroFilePos(t *testing.T) {
+	for tk := range NewSimpleTokenizer([]byte("hello world")).Tokenize() {
+		if tk.FilePos != 0 {
+			t.Errorf("FilePos = %d, want 0 for a tokenizer not bound to a File", tk.FilePos)
+		}
+	}
+}
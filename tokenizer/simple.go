@@ -19,7 +19,10 @@ import (
 	"fmt"
 	"iter"
 	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
 )
 
 const (
@@ -27,37 +30,128 @@ const (
 	MARKER_PER_TOKENS = 100
 )
 
+// TokenPos identifies a Token, either as its ordinal position in the token
+// stream (the TokenPos field, as produced by every SimpleTokenizer) or, for
+// a tokenizer bound to a FileSet File via NewSimpleTokenizerIn, as a global
+// byte offset resolvable through FileSet.Position.
+type TokenPos = int64
+
 type Token struct {
 	TokenBytes [1 + MAX_TOKEN_SIZE]byte
-	TokenPos   int64
+	TokenPos   TokenPos
+
+	// FilePos is the byte offset of the start of this token in the FileSet
+	// that produced it, or zero if the tokenizer was not bound to a File.
+	FilePos TokenPos
 }
 
 type SimpleTokenizer struct {
 	// input and output
 	input []byte
 
+	// file is the FileSet File this tokenizer is bound to, if any, used to
+	// populate Token.FilePos and to record line offsets for Position lookup.
+	file *File
+
+	// encoding is the encoding.Encoding input was decoded from, set when the
+	// tokenizer was constructed with WithEncoding or WithAutoDetectEncoding.
+	encoding encoding.Encoding
+
+	// stopwords, if set via WithStopwords, suppresses matching tokens.
+	stopwords *Dictionary
+
+	// phraseDict, if set via WithPhraseDict, is matched against the whole
+	// input up front; phraseMatches holds the resulting non-overlapping
+	// matches in input order, and nextPhraseMatch is how far into them
+	// Tokenize has consumed.
+	phraseDict      *Dictionary
+	phraseMatches   []Match
+	nextPhraseMatch int
+
+	// cjkSegmenter splits each CJK run into tokens; defaults to
+	// NGramSegmenter{N: 3} (see bindConfig).
+	cjkSegmenter CJKSegmenter
+
 	// the buffer to store the _token
 	begin        int
 	currTokenPos int
 	nextMarker   int
 	latinBuf     bytes.Buffer
 
+	// streamBase is the number of input bytes that have been discarded from
+	// t.input so far (e.g. by StreamTokenizer.refill compacting the buffer).
+	// It is added to every byte offset reported via incrTokenCount, so a
+	// _MARKER_N token's TokenPos stays a stable global offset across
+	// refills instead of being rebased to the local post-compaction buffer.
+	streamBase int64
+
 	Done bool
 	Err  error
 }
 
-func NewSimpleTokenizer(input []byte) *SimpleTokenizer {
-	return &SimpleTokenizer{input: input, nextMarker: MARKER_PER_TOKENS}
+func NewSimpleTokenizer(input []byte, opts ...Option) *SimpleTokenizer {
+	cfg := newTokenizerConfig(opts)
+	decoded, enc, err := decodeBytes(cfg, input)
+	t := &SimpleTokenizer{input: decoded, nextMarker: MARKER_PER_TOKENS, encoding: enc}
+	t.Err = err
+	t.bindConfig(cfg)
+	return t
+}
+
+// NewSimpleTokenizerIn binds the tokenizer to File f, so that every emitted
+// Token carries a FilePos resolvable via f's FileSet.Position.
+func NewSimpleTokenizerIn(f *File, input []byte, opts ...Option) *SimpleTokenizer {
+	cfg := newTokenizerConfig(opts)
+	decoded, enc, err := decodeBytes(cfg, input)
+	t := &SimpleTokenizer{input: decoded, file: f, nextMarker: MARKER_PER_TOKENS, encoding: enc}
+	t.Err = err
+	t.bindConfig(cfg)
+	return t
+}
+
+func (t *SimpleTokenizer) bindConfig(cfg *tokenizerConfig) {
+	t.stopwords = cfg.stopwords
+	t.phraseDict = cfg.phraseDict
+	if t.phraseDict != nil {
+		t.phraseMatches = t.phraseDict.FindMatches(t.input)
+	}
+	t.cjkSegmenter = cfg.cjkSegmenter
+	if t.cjkSegmenter == nil {
+		t.cjkSegmenter = NGramSegmenter{N: 3}
+	}
+}
+
+// filePos returns the FileSet-global byte offset for the local byte offset
+// pos into t.input, or 0 if t is not bound to a File.
+func (t *SimpleTokenizer) filePos(pos int) TokenPos {
+	if t.file == nil {
+		return 0
+	}
+	return TokenPos(t.file.base + pos)
 }
 
 func isBreakerRune(rune rune) bool {
-	// See ASCII table
-	return rune < 33 || rune == 127 || rune == utf8.RuneError
+	if !utf8.ValidRune(rune) || rune == utf8.RuneError {
+		return true
+	}
+	return unicode.IsSpace(rune) || unicode.IsPunct(rune) || unicode.IsControl(rune)
+}
+
+// isCJK reports whether rune belongs to one of the CJK scripts that are
+// tokenized via outputCJK (split by the CJKSegmenter) instead of being treated as a
+// word in an alphabetic script.
+func isCJK(rune rune) bool {
+	return unicode.Is(unicode.Han, rune) || unicode.Is(unicode.Hiragana, rune) ||
+		unicode.Is(unicode.Katakana, rune) || unicode.Is(unicode.Hangul, rune)
 }
 
-// Assume we already tested isBreakerRune.  Test if rune is 1 or 2 byte UTF-8
+// Assume we already tested isBreakerRune.  Test if rune belongs to an
+// alphabetic (non-CJK) script, e.g. Latin, Cyrillic, Greek, Arabic, Devanagari.
 func isLatin(rune rune) bool {
-	return rune < 0x7FF
+	if !unicode.IsLetter(rune) && !unicode.IsDigit(rune) {
+		return false
+	}
+	return !isCJK(rune)
 }
 
 type handler func(t *SimpleTokenizer, pos int, rune rune, yield func(Token) bool) handler
@@ -130,7 +224,8 @@ func (t *SimpleTokenizer) incrTokenCount(n int, pos int, yield func(Token) bool)
 		token := Token{}
 		token.TokenBytes[0] = byte(len(mkStr))
 		copy(token.TokenBytes[1:], []byte(mkStr))
-		token.TokenPos = int64(pos)
+		token.TokenPos = t.streamBase + int64(pos)
+		token.FilePos = t.filePos(pos)
 		if !yield(token) {
 			t.Done = true
 		}
@@ -146,15 +241,13 @@ func (t *SimpleTokenizer) outputLatin(pos int, yield func(Token) bool) {
 	t.latinBuf.Reset()
 
 	ibuf := t.input[t.begin:pos]
-	for i := 0; i < len(ibuf); i++ {
-		if ibuf[i] > 127 {
-			if t.latinBuf.Len() >= MAX_TOKEN_SIZE-1 {
+	for i := 0; i < len(ibuf); {
+		r, size := utf8.DecodeRune(ibuf[i:])
+		if r >= utf8.RuneSelf {
+			if t.latinBuf.Len()+size > MAX_TOKEN_SIZE {
 				break
-			} else {
-				t.latinBuf.WriteByte(ibuf[i])
-				t.latinBuf.WriteByte(ibuf[i+1])
-				i += 1
 			}
+			t.latinBuf.WriteRune(r)
 		} else {
 			if t.latinBuf.Len() >= MAX_TOKEN_SIZE {
 				break
@@ -163,52 +256,103 @@ func (t *SimpleTokenizer) outputLatin(pos int, yield func(Token) bool) {
 				t.latinBuf.WriteByte(ibuf[i])
 			}
 		}
+		i += size
 	}
 
 	if t.latinBuf.Len() > 1 {
 		ls := strings.ToLower(t.latinBuf.String())
-		token := Token{}
-		token.TokenBytes[0] = byte(len(ls))
-		copy(token.TokenBytes[1:], []byte(ls))
-		token.TokenPos = int64(t.currTokenPos)
-		if !yield(token) {
-			t.Done = true
-			return
+		if t.stopwords == nil || !t.stopwords.Contains([]byte(ls)) {
+			token := Token{}
+			token.TokenBytes[0] = byte(len(ls))
+			copy(token.TokenBytes[1:], []byte(ls))
+			token.TokenPos = int64(t.currTokenPos)
+			token.FilePos = t.filePos(t.begin)
+			if !yield(token) {
+				t.Done = true
+				return
+			}
 		}
 	}
 	t.incrTokenCount(1, pos, yield)
 }
 
-// outputCJK outputs the CJK token from t.begin to pos
-// if token contains latin letter, we do not normalize like outputLatin
+// outputCJK outputs the CJK tokens from t.begin to pos, split into spans by
+// t.cjkSegmenter (trigrams by default; see WithCJKSegmenter).
+// if a span contains a latin letter, we do not normalize like outputLatin
 func (t *SimpleTokenizer) outputCJK(pos int, yield func(Token) bool) {
 	ibuf := t.input[t.begin:pos]
-	ia := 0
-	_, ib := utf8.DecodeRune(ibuf)
-	_, sz := utf8.DecodeRune(ibuf[ib:])
-	ic := ib + sz
-	_, sz = utf8.DecodeRune(ibuf[ic:])
-	id := ic + sz
-
-	for ia < id {
-		token := Token{}
-		token.TokenBytes[0] = byte(id - ia)
-		copy(token.TokenBytes[1:], ibuf[ia:id])
-		token.TokenPos = int64(t.currTokenPos)
-		if !yield(token) {
-			t.Done = true
-			return
+
+	for _, span := range t.cjkSegmenter.Segment(ibuf) {
+		ia, id := span[0], span[1]
+		if t.stopwords == nil || !t.stopwords.Contains(ibuf[ia:id]) {
+			n := id - ia
+			if n > MAX_TOKEN_SIZE {
+				n = MAX_TOKEN_SIZE
+			}
+			token := Token{}
+			token.TokenBytes[0] = byte(n)
+			copy(token.TokenBytes[1:], ibuf[ia:ia+n])
+			token.TokenPos = int64(t.currTokenPos)
+			token.FilePos = t.filePos(t.begin + ia)
+			if !yield(token) {
+				t.Done = true
+				return
+			}
 		}
 		t.incrTokenCount(1, t.begin+ia, yield)
 		if t.Done {
 			return
 		}
+	}
+}
+
+// emitPhrase yields a single token carrying m's raw bytes in place of the
+// default Latin/CJK handling for the span it covers.
+func (t *SimpleTokenizer) emitPhrase(m Match, yield func(Token) bool) {
+	ibuf := t.input[m.Start : m.Start+m.Len]
+	n := len(ibuf)
+	if n > MAX_TOKEN_SIZE {
+		n = MAX_TOKEN_SIZE
+	}
+	token := Token{}
+	token.TokenBytes[0] = byte(n)
+	copy(token.TokenBytes[1:], ibuf[:n])
+	token.TokenPos = int64(t.currTokenPos)
+	token.FilePos = t.filePos(m.Start)
+	if !yield(token) {
+		t.Done = true
+		return
+	}
+	t.incrTokenCount(1, m.Start+m.Len, yield)
+}
 
-		ia = ib
-		ib = ic
-		ic = id
-		_, sz = utf8.DecodeRune(ibuf[id:])
-		id += sz
+// nextPhraseMatchAt reports the phrase match (if any) that starts exactly
+// at pos, advancing past any matches that start earlier than pos (which
+// can no longer be cleanly anchored, since the tokenizer already passed
+// them without starting a new run there).
+func (t *SimpleTokenizer) nextPhraseMatchAt(pos int) (Match, bool) {
+	for t.nextPhraseMatch < len(t.phraseMatches) && t.phraseMatches[t.nextPhraseMatch].Start < pos {
+		t.nextPhraseMatch++
+	}
+	if t.nextPhraseMatch < len(t.phraseMatches) && t.phraseMatches[t.nextPhraseMatch].Start == pos {
+		return t.phraseMatches[t.nextPhraseMatch], true
+	}
+	return Match{}, false
+}
+
+// flushPendingRun closes out whatever Latin or CJK run is accumulated in
+// t.input[t.begin:pos] (a no-op if nothing is pending), so that a phrase
+// match starting at pos can interrupt a run partway through, e.g. a CJK
+// run with no breakers anywhere near the phrase.
+func (t *SimpleTokenizer) flushPendingRun(pos int, yield func(Token) bool) {
+	if t.begin >= pos {
+		return
+	}
+	r, _ := utf8.DecodeRune(t.input[t.begin:])
+	if isLatin(r) {
+		t.outputLatin(pos, yield)
+	} else if isCJK(r) {
+		t.outputCJK(pos, yield)
 	}
 }
 
@@ -219,16 +363,43 @@ func (t *SimpleTokenizer) Tokenize() iter.Seq[Token] {
 		}
 
 		h := beginToken
+		pos := 0
 
-		for pos, rune := range string(t.input) {
+		for pos < len(t.input) {
 			if t.Done {
 				return
 			}
 
+			if t.phraseDict != nil {
+				if m, ok := t.nextPhraseMatchAt(pos); ok {
+					t.flushPendingRun(pos, yield)
+					if t.Done {
+						return
+					}
+					t.nextPhraseMatch++
+					t.emitPhrase(m, yield)
+					if t.Done {
+						return
+					}
+					t.begin = m.Start + m.Len
+					pos = t.begin
+					h = beginToken
+					continue
+				}
+			}
+
+			rune, size := utf8.DecodeRune(t.input[pos:])
+
+			if rune == '\n' && t.file != nil {
+				t.file.AddLine(pos + 1)
+			}
+
 			h = h(t, pos, rune, yield)
 			if h == nil {
 				break
 			}
+
+			pos += size
 		}
 
 		// send a space to output last token
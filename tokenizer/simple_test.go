@@ -19,8 +19,8 @@ import (
 	"testing"
 )
 
-func tokenize(input []byte) []Token {
-	tknz := NewSimpleTokenizer(input)
+func tokenize(input []byte, opts ...Option) []Token {
+	tknz := NewSimpleTokenizer(input, opts...)
 	var tokens []Token
 	for t := range tknz.Tokenize() {
 		tokens = append(tokens, t)
@@ -28,8 +28,8 @@ func tokenize(input []byte) []Token {
 	return tokens
 }
 
-func checkTokenize(t *testing.T, input string, checkTokens []Token) {
-	tks := tokenize([]byte(input))
+func checkTokenize(t *testing.T, input string, checkTokens []Token, opts ...Option) {
+	tks := tokenize([]byte(input), opts...)
 	if len(tks) != len(checkTokens) {
 		t.Errorf("tokenize(%s) = %v, want %v", input, tks, checkTokens)
 		return
@@ -53,26 +53,25 @@ func makeToken(token string, pos int64) Token {
 func TestLatin(t *testing.T) {
 	checkTokenize(t, "hello, world", []Token{
 		makeToken("hello", 0),
-		makeToken("world", 1),
+		makeToken("world", 2),
 	})
 	checkTokenize(t, "hello, world!   From Me.", []Token{
 		makeToken("hello", 0),
-		makeToken("world", 1),
-		makeToken("from", 3),
-		makeToken("me", 4),
+		makeToken("world", 2),
+		makeToken("from", 4),
+		makeToken("me", 5),
 	})
 	checkTokenize(t, "  H1N1 Covid19 a b@b\nc3", []Token{
 		makeToken("h1n1", 1),
 		makeToken("covid19", 2),
-		makeToken("bb", 4),
-		makeToken("c3", 6),
+		makeToken("c3", 8),
 	})
 	checkTokenize(t, "À bon chat, bon rat", []Token{
 		makeToken(strings.ToLower("À"), 0),
 		makeToken("bon", 1),
 		makeToken("chat", 2),
-		makeToken("bon", 3),
-		makeToken("rat", 4),
+		makeToken("bon", 4),
+		makeToken("rat", 5),
 	})
 	checkTokenize(t, "Mieux vaut prévenir que guérir", []Token{
 		makeToken("mieux", 0),
@@ -103,9 +102,9 @@ func TestCJK(t *testing.T) {
 	})
 	checkTokenize(t, "I come, I see, I征服", []Token{
 		makeToken("come", 1),
-		makeToken("see", 3),
-		makeToken("征服", 5),
-		makeToken("服", 6),
+		makeToken("see", 4),
+		makeToken("征服", 7),
+		makeToken("服", 8),
 	})
 	checkTokenize(t, "中华铅笔2B的好用, 6B的太软了", []Token{
 		makeToken("中华铅", 0),
@@ -115,9 +114,8 @@ func TestCJK(t *testing.T) {
 		makeToken("2B的", 4),
 		makeToken("B的好", 5),
 		makeToken("的好用", 6),
-		makeToken("好用,", 7),
-		makeToken("用,", 8),
-		makeToken(",", 9),
+		makeToken("好用", 7),
+		makeToken("用", 8),
 		makeToken("6b", 10),
 		makeToken("的太软", 11),
 		makeToken("太软了", 12),
@@ -0,0 +1,137 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import (
+	"io"
+	"iter"
+	"unicode/utf8"
+)
+
+// DEFAULT_CHUNK_SIZE is the amount of fresh data read from the underlying
+// io.Reader on each refill.  The internal buffer can grow beyond this when a
+// single token (e.g. a long run of breakers) straddles a refill boundary.
+const DEFAULT_CHUNK_SIZE = 64 * 1024
+
+// StreamTokenizer tokenizes data pulled incrementally from an io.Reader, so
+// that callers never have to hold the whole input in memory at once.  It
+// reuses the same state machine as SimpleTokenizer: the buffer is compacted
+// down to the unconsumed tail (t.begin onward) on every refill, so a token
+// that straddles a chunk boundary simply keeps accumulating until it can be
+// emitted.
+type StreamTokenizer struct {
+	*SimpleTokenizer
+
+	r         io.Reader
+	chunkSize int
+	pos       int
+	h         handler
+	eof       bool
+}
+
+// NewStreamTokenizer creates a StreamTokenizer reading from r. With
+// WithEncoding or WithAutoDetectEncoding, r is transparently decoded to
+// UTF-8 before the state machine runs.
+func NewStreamTokenizer(r io.Reader, opts ...Option) *StreamTokenizer {
+	cfg := newTokenizerConfig(opts)
+	decoded, enc, err := decodeReader(cfg, r)
+	segmenter := cfg.cjkSegmenter
+	if segmenter == nil {
+		segmenter = NGramSegmenter{N: 3}
+	}
+	return &StreamTokenizer{
+		SimpleTokenizer: &SimpleTokenizer{
+			nextMarker:   MARKER_PER_TOKENS,
+			encoding:     enc,
+			stopwords:    cfg.stopwords,
+			cjkSegmenter: segmenter,
+			Err:          err,
+		},
+		r:         decoded,
+		chunkSize: DEFAULT_CHUNK_SIZE,
+		h:         beginToken,
+	}
+}
+
+// refill compacts the buffer down to the unconsumed tail (starting at
+// t.begin) and appends up to t.chunkSize freshly read bytes.
+func (t *StreamTokenizer) refill() error {
+	if t.begin > 0 {
+		t.streamBase += int64(t.begin)
+		copy(t.input, t.input[t.begin:])
+		t.input = t.input[:len(t.input)-t.begin]
+		t.pos -= t.begin
+		t.begin = 0
+	}
+
+	old := len(t.input)
+	t.input = append(t.input, make([]byte, t.chunkSize)...)
+	n, err := t.r.Read(t.input[old:])
+	t.input = t.input[:old+n]
+	if err != nil {
+		if err == io.EOF {
+			t.eof = true
+		} else {
+			t.Err = err
+			t.Done = true
+		}
+		return err
+	}
+	if n == 0 {
+		t.eof = true
+	}
+	return nil
+}
+
+// Tokenize returns the same iter.Seq[Token] API as SimpleTokenizer.Tokenize,
+// refilling its internal buffer from r as the state machine catches up to
+// the end of the buffered data.
+func (t *StreamTokenizer) Tokenize() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			if t.Done {
+				return
+			}
+			if t.pos >= len(t.input) {
+				if t.eof {
+					t.h(t.SimpleTokenizer, len(t.input), ' ', yield)
+					return
+				}
+				if err := t.refill(); err != nil && err != io.EOF {
+					return
+				}
+				if t.pos >= len(t.input) && t.eof {
+					t.h(t.SimpleTokenizer, len(t.input), ' ', yield)
+					return
+				}
+				continue
+			}
+
+			if !utf8.FullRune(t.input[t.pos:]) && !t.eof {
+				if err := t.refill(); err != nil && err != io.EOF {
+					return
+				}
+				continue
+			}
+
+			rune, size := utf8.DecodeRune(t.input[t.pos:])
+			t.h = t.h(t.SimpleTokenizer, t.pos, rune, yield)
+			t.pos += size
+			if t.h == nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// tinyReader forces a 1-byte-at-a-time Read, so every refill lands mid-rune
+// and mid-token at least once.
+type tinyReader struct {
+	data []byte
+}
+
+func (r *tinyReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[:1])
+	r.data = r.data[1:]
+	return n, nil
+}
+
+func streamTokenize(r io.Reader) []Token {
+	tknz := NewStreamTokenizer(r)
+	var tokens []Token
+	for t := range tknz.Tokenize() {
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func checkStreamMatchesSimple(t *testing.T, input string) {
+	want := tokenize([]byte(input))
+	got := streamTokenize(&tinyReader{data: []byte(input)})
+	if len(got) != len(want) {
+		t.Errorf("streamTokenize(%s) = %v, want %v", input, got, want)
+		return
+	}
+	for i, tk := range got {
+		if tk != want[i] {
+			t.Errorf("streamTokenize(%s) = %v, want %v", input, got, want)
+			return
+		}
+	}
+}
+
+func TestStreamMatchesSimple(t *testing.T) {
+	checkStreamMatchesSimple(t, "hello, world")
+	checkStreamMatchesSimple(t, "hello, world!   From Me.")
+	checkStreamMatchesSimple(t, "相见时难别亦难")
+	checkStreamMatchesSimple(t, "I come, I see, I征服")
+	checkStreamMatchesSimple(t, "中华铅笔2B的好用, 6B的太软了")
+}
+
+func TestStreamSmallChunkSize(t *testing.T) {
+	input := "中华铅笔2B的好用, 6B的太软了 hello world"
+	tknz := NewStreamTokenizer(bytes.NewReader([]byte(input)))
+	tknz.chunkSize = 1
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+
+	want := tokenize([]byte(input))
+	if len(got) != len(want) {
+		t.Errorf("Tokenize() with chunkSize=1 = %v, want %v", got, want)
+		return
+	}
+	for i, tk := range got {
+		if tk != want[i] {
+			t.Errorf("Tokenize() with chunkSize=1 = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+// findMarkerPos returns the TokenPos stamped on the _MARKER_<marker> token
+// in tokens, if present.
+func findMarkerPos(tokens []Token, marker string) (TokenPos, bool) {
+	var mk Token
+	mkStr := "_MARKER_" + marker
+	mk.TokenBytes[0] = byte(len(mkStr))
+	copy(mk.TokenBytes[1:], []byte(mkStr))
+	for _, tk := range tokens {
+		if tk.TokenBytes == mk.TokenBytes {
+			return tk.TokenPos, true
+		}
+	}
+	return 0, false
+}
+
+// TestStreamMarkerPosAcrossRefills checks that a _MARKER_100 token's
+// TokenPos survives many small refills as the same global byte offset it
+// would have if the whole input were tokenized in one pass, rather than
+// being rebased to the local, post-compaction buffer offset.
+func TestStreamMarkerPosAcrossRefills(t *testing.T) {
+	words := make([]string, 150)
+	for i := range words {
+		words[i] = "word"
+	}
+	input := strings.Join(words, " ")
+
+	want := tokenize([]byte(input))
+	wantPos, ok := findMarkerPos(want, "100")
+	if !ok {
+		t.Fatal("_MARKER_100 not found in reference tokenization")
+	}
+
+	tknz := NewStreamTokenizer(bytes.NewReader([]byte(input)))
+	tknz.chunkSize = 3
+	var got []Token
+	for tk := range tknz.Tokenize() {
+		got = append(got, tk)
+	}
+
+	gotPos, ok := findMarkerPos(got, "100")
+	if !ok {
+		t.Fatal("_MARKER_100 not found in stream tokenization")
+	}
+	if gotPos != wantPos {
+		t.Errorf("_MARKER_100 TokenPos = %d after refills, want %d (true byte offset in source)", gotPos, wantPos)
+	}
+}
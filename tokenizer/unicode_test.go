@@ -0,0 +1,75 @@
+// Copyright 2024 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizer
+
+import "testing"
+
+func TestUnicodeScripts(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{
+			name:  "cyrillic words",
+			input: "Привет, мир",
+			want: []Token{
+				makeToken("привет", 0),
+				makeToken("мир", 2),
+			},
+		},
+		{
+			name:  "fullwidth cjk punctuation as breakers",
+			input: "你好，世界！真的「吗」……是的",
+			want: []Token{
+				makeToken("你好", 0),
+				makeToken("好", 1),
+				makeToken("世界", 3),
+				makeToken("界", 4),
+				makeToken("真的", 6),
+				makeToken("的", 7),
+				makeToken("吗", 9),
+				makeToken("是的", 11),
+				makeToken("的", 12),
+			},
+		},
+		{
+			name:  "mixed arabic and latin",
+			input: "hello مرحبا world",
+			want: []Token{
+				makeToken("hello", 0),
+				makeToken("مرحبا", 1),
+				makeToken("world", 2),
+			},
+		},
+		{
+			// a 3-byte-encoded script with an odd rune count (9 bytes total)
+			// used to misalign outputLatin's hand-rolled 2-byte pairing and
+			// panic with an out-of-range index.
+			name:  "devanagari odd rune count",
+			input: "कमल नमन",
+			want: []Token{
+				makeToken("कमल", 0),
+				makeToken("नमन", 1),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checkTokenize(t, c.input, c.want)
+		})
+	}
+}